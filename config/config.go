@@ -0,0 +1,46 @@
+package config
+
+import "github.com/future-architect/vuls/logging"
+
+// Version and Revision are set via ldflags at build time.
+var (
+	Version  = "0.0.0"
+	Revision = "build-from-source"
+)
+
+// Conf is the global configuration, populated from the config file and CLI flags.
+var Conf Config
+
+// Config holds every setting Detect and its pipeline need.
+type Config struct {
+	Lang    string
+	LogOpts logging.LogOpts
+
+	Servers map[string]ServerInfo
+
+	CveDict  GoCveDictConf
+	OvalDict GovalDictConf
+	Gost     GostConf
+	OSV      OSVConf
+
+	Exploit    ExploitConf
+	Metasploit MetasploitConf
+	KEVuln     KEVulnConf
+	WpScan     WpScanConf
+
+	TrivyCacheDBDir string
+	NoProgress      bool
+	RefreshCve      bool
+
+	DiffPlus   bool
+	DiffMinus  bool
+	ResultsDir string
+
+	CvssScoreOver       float64
+	ConfidenceScoreOver int
+	IgnoreUnfixed       bool
+	IgnoreUnscoredCves  bool
+	IgnoreStatuses      []string
+
+	DetectConcurrency int
+}
@@ -0,0 +1,82 @@
+package config
+
+// ServerInfo is a single scan target's configuration, as read from the
+// config file's [servers.<name>] section.
+type ServerInfo struct {
+	ServerName string
+
+	CpeNames         []string
+	OwaspDCXMLPath   string
+	GitHubRepos      map[string]GitHubConf
+	IgnoreCves       []string
+	IgnorePkgsRegexp []string
+	IgnoredJSONKeys  []string
+	VEXPaths         []string
+
+	Containers map[string]ContainerSetting
+}
+
+// ContainerSetting is a single container's configuration, nested under its
+// host's ServerInfo.
+type ContainerSetting struct {
+	Cpes             []string
+	OwaspDCXMLPath   string
+	IgnoreCves       []string
+	IgnorePkgsRegexp []string
+	VEXPaths         []string
+}
+
+// GitHubConf configures access to a single GitHub repo's Security Alerts.
+type GitHubConf struct {
+	Token                 string
+	IgnoreGitHubDismissed bool
+}
+
+// WpScanConf configures WordPress vulnerability detection.
+type WpScanConf struct {
+	Token          string
+	DetectInactive bool
+}
+
+// GoCveDictConf configures the go-cve-dictionary client.
+type GoCveDictConf struct {
+	URL         string
+	SQLite3Path string
+}
+
+// GovalDictConf configures the goval-dictionary client.
+type GovalDictConf struct {
+	URL         string
+	SQLite3Path string
+}
+
+// GostConf configures the gost client.
+type GostConf struct {
+	URL         string
+	SQLite3Path string
+}
+
+// OSVConf configures OSV-schema advisory ingestion: a local directory of
+// OSV JSON files (Path) and/or a URL serving one OSV JSON entry (URL). The
+// URL's body is parsed as a single entry; an array or archive of entries is
+// not read from it. Both may be set; at least one must be for
+// DetectPkgCvesWithOSV to do anything.
+type OSVConf struct {
+	Path string
+	URL  string
+}
+
+// ExploitConf configures the Exploit-DB lookup.
+type ExploitConf struct {
+	URL string
+}
+
+// MetasploitConf configures the Metasploit module lookup.
+type MetasploitConf struct {
+	URL string
+}
+
+// KEVulnConf configures the CISA Known Exploited Vulnerabilities lookup.
+type KEVulnConf struct {
+	URL string
+}
@@ -0,0 +1,105 @@
+//go:build !scanner
+// +build !scanner
+
+package detector
+
+import (
+	"sync"
+
+	"golang.org/x/xerrors"
+
+	"github.com/future-architect/vuls/config"
+	"github.com/future-architect/vuls/gost"
+	"github.com/future-architect/vuls/logging"
+	"github.com/future-architect/vuls/oval"
+)
+
+// detectClients holds the DB clients Detect's per-server pipeline needs.
+// They are opened once and shared across every goroutine in the worker
+// pool, instead of each scanned host paying connection setup cost against
+// goval-dictionary, go-cve-dictionary and gost.
+type detectClients struct {
+	cveDict *goCveDictClient
+
+	ovalCnf config.GovalDictConf
+	gostCnf config.GostConf
+	logOpts logging.LogOpts
+
+	mu          sync.Mutex
+	ovalClients map[string]oval.Client
+	gostClients map[string]gost.Client
+}
+
+// newDetectClients opens the family-independent go-cve-dictionary client
+// eagerly. OVAL and gost clients are family-scoped, so they're opened
+// lazily the first time a given r.Family is seen and cached from then on.
+func newDetectClients(cveDictCnf config.GoCveDictConf, ovalCnf config.GovalDictConf, gostCnf config.GostConf, logOpts logging.LogOpts) (*detectClients, error) {
+	cveDict, err := newGoCveDictClient(&cveDictCnf, logOpts)
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to newGoCveDictClient. err: %w", err)
+	}
+	return &detectClients{
+		cveDict:     cveDict,
+		ovalCnf:     ovalCnf,
+		gostCnf:     gostCnf,
+		logOpts:     logOpts,
+		ovalClients: map[string]oval.Client{},
+		gostClients: map[string]gost.Client{},
+	}, nil
+}
+
+// oval returns the OVAL client for family, opening and caching it on first
+// use. Families are requested concurrently by the worker pool, so access is
+// serialized by mu.
+func (c *detectClients) oval(family string) (oval.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cl, ok := c.ovalClients[family]; ok {
+		return cl, nil
+	}
+	cl, err := oval.NewOVALClient(family, c.ovalCnf, c.logOpts)
+	if err != nil {
+		return nil, err
+	}
+	c.ovalClients[family] = cl
+	return cl, nil
+}
+
+// gost returns the gost client for family, opening and caching it on first use.
+func (c *detectClients) gost(family string) (gost.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cl, ok := c.gostClients[family]; ok {
+		return cl, nil
+	}
+	cl, err := gost.NewGostClient(c.gostCnf, family, c.logOpts)
+	if err != nil {
+		return nil, err
+	}
+	c.gostClients[family] = cl
+	return cl, nil
+}
+
+// Close closes every client opened so far. Errors closing individual
+// family-scoped clients are logged rather than returned, matching how the
+// per-call client close used to be handled before this client was shared.
+func (c *detectClients) Close() error {
+	var cveDictErr error
+	if err := c.cveDict.closeDB(); err != nil {
+		cveDictErr = xerrors.Errorf("Failed to close go-cve-dictionary DB. err: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for family, cl := range c.ovalClients {
+		if err := cl.CloseDB(); err != nil {
+			logging.Log.Errorf("Failed to close the OVAL DB for %s. err: %+v", family, err)
+		}
+	}
+	for family, cl := range c.gostClients {
+		if err := cl.CloseDB(); err != nil {
+			logging.Log.Errorf("Failed to close the gost DB for %s. err: %+v", family, err)
+		}
+	}
+	return cveDictErr
+}
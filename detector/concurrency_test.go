@@ -0,0 +1,26 @@
+//go:build !scanner
+// +build !scanner
+
+package detector
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestResolveConcurrency(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured int
+		want       int
+	}{
+		{"positive value is used as-is", 4, 4},
+		{"zero falls back to NumCPU", 0, runtime.NumCPU()},
+		{"negative falls back to NumCPU", -1, runtime.NumCPU()},
+	}
+	for _, tt := range tests {
+		if got := resolveConcurrency(tt.configured); got != tt.want {
+			t.Errorf("%s: resolveConcurrency(%d) = %d, want %d", tt.name, tt.configured, got, tt.want)
+		}
+	}
+}
@@ -5,9 +5,11 @@ package detector
 
 import (
 	"os"
+	"runtime"
 	"strings"
 	"time"
 
+	"golang.org/x/sync/errgroup"
 	"golang.org/x/xerrors"
 
 	"github.com/future-architect/vuls/config"
@@ -17,7 +19,6 @@ import (
 	"github.com/future-architect/vuls/gost"
 	"github.com/future-architect/vuls/logging"
 	"github.com/future-architect/vuls/models"
-	"github.com/future-architect/vuls/oval"
 	"github.com/future-architect/vuls/reporter"
 	"github.com/future-architect/vuls/util"
 	cvemodels "github.com/vulsio/go-cve-dictionary/models"
@@ -29,105 +30,160 @@ type Cpe struct {
 	UseJVN bool
 }
 
+// resolveConcurrency returns the number of servers Detect's worker pool
+// scans at once: the configured DetectConcurrency, or runtime.NumCPU() when
+// it's unset (<=0).
+func resolveConcurrency(configured int) int {
+	if configured <= 0 {
+		return runtime.NumCPU()
+	}
+	return configured
+}
+
 // Detect vulns and fill CVE detailed information
 func Detect(rs []models.ScanResult, dir string) ([]models.ScanResult, error) {
 
 	// Use the same reportedAt for all rs
 	reportedAt := time.Now()
-	for i, r := range rs {
-		if !config.Conf.RefreshCve && !needToRefreshCve(r) {
-			logging.Log.Info("No need to refresh")
-			continue
-		}
 
-		if !reuseScannedCves(&r) {
-			r.ScannedCves = models.VulnInfos{}
+	clients, err := newDetectClients(config.Conf.CveDict, config.Conf.OvalDict, config.Conf.Gost, config.Conf.LogOpts)
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to prepare shared DB clients: %w", err)
+	}
+	defer func() {
+		if err := clients.Close(); err != nil {
+			logging.Log.Errorf("Failed to close shared DB clients. err: %+v", err)
 		}
+	}()
 
-		if err := DetectLibsCves(&r, config.Conf.TrivyCacheDBDir, config.Conf.NoProgress); err != nil {
-			return nil, xerrors.Errorf("Failed to fill with Library dependency: %w", err)
-		}
+	concurrency := resolveConcurrency(config.Conf.DetectConcurrency)
+
+	eg := errgroup.Group{}
+	eg.SetLimit(concurrency)
+	for i := range rs {
+		i := i
+		eg.Go(func() error {
+			r := rs[i]
+			if !config.Conf.RefreshCve && !needToRefreshCve(r) {
+				logging.Log.Info("No need to refresh")
+				return nil
+			}
 
-		if err := DetectPkgCves(&r, config.Conf.OvalDict, config.Conf.Gost, config.Conf.LogOpts); err != nil {
-			return nil, xerrors.Errorf("Failed to detect Pkg CVE: %w", err)
-		}
+			if !reuseScannedCves(&r) {
+				r.ScannedCves = models.VulnInfos{}
+			}
 
-		cpeURIs, owaspDCXMLPath := []string{}, ""
-		cpes := []Cpe{}
-		if len(r.Container.ContainerID) == 0 {
-			cpeURIs = config.Conf.Servers[r.ServerName].CpeNames
-			owaspDCXMLPath = config.Conf.Servers[r.ServerName].OwaspDCXMLPath
-		} else {
-			if s, ok := config.Conf.Servers[r.ServerName]; ok {
-				if con, ok := s.Containers[r.Container.Name]; ok {
-					cpeURIs = con.Cpes
-					owaspDCXMLPath = con.OwaspDCXMLPath
+			if err := DetectLibsCves(&r, config.Conf.TrivyCacheDBDir, config.Conf.NoProgress); err != nil {
+				return xerrors.Errorf("Failed to fill with Library dependency: %w", err)
+			}
+
+			if err := DetectPkgCves(&r, clients); err != nil {
+				return xerrors.Errorf("Failed to detect Pkg CVE: %w", err)
+			}
+
+			if err := DetectPkgCvesWithOSV(&r, config.Conf.OSV, config.Conf.LogOpts); err != nil {
+				return xerrors.Errorf("Failed to detect CVE with OSV: %w", err)
+			}
+
+			if err := DetectGoBinaryCves(&r, config.Conf.TrivyCacheDBDir); err != nil {
+				return xerrors.Errorf("Failed to detect CVE in Go binaries: %w", err)
+			}
+
+			cpeURIs, owaspDCXMLPath := []string{}, ""
+			cpes := []Cpe{}
+			if len(r.Container.ContainerID) == 0 {
+				cpeURIs = config.Conf.Servers[r.ServerName].CpeNames
+				owaspDCXMLPath = config.Conf.Servers[r.ServerName].OwaspDCXMLPath
+			} else {
+				if s, ok := config.Conf.Servers[r.ServerName]; ok {
+					if con, ok := s.Containers[r.Container.Name]; ok {
+						cpeURIs = con.Cpes
+						owaspDCXMLPath = con.OwaspDCXMLPath
+					}
 				}
 			}
-		}
-		if owaspDCXMLPath != "" {
-			cpes, err := parser.Parse(owaspDCXMLPath)
-			if err != nil {
-				return nil, xerrors.Errorf("Failed to read OWASP Dependency Check XML on %s, `%s`, err: %w",
-					r.ServerInfo(), owaspDCXMLPath, err)
+			if owaspDCXMLPath != "" {
+				cpes, err := parser.Parse(owaspDCXMLPath)
+				if err != nil {
+					return xerrors.Errorf("Failed to read OWASP Dependency Check XML on %s, `%s`, err: %w",
+						r.ServerInfo(), owaspDCXMLPath, err)
+				}
+				cpeURIs = append(cpeURIs, cpes...)
+			}
+			for _, uri := range cpeURIs {
+				cpes = append(cpes, Cpe{
+					CpeURI: uri,
+					UseJVN: true,
+				})
+			}
+			if err := DetectCpeURIsCves(&r, cpes, clients.cveDict); err != nil {
+				return xerrors.Errorf("Failed to detect CVE of `%s`: %w", cpeURIs, err)
 			}
-			cpeURIs = append(cpeURIs, cpes...)
-		}
-		for _, uri := range cpeURIs {
-			cpes = append(cpes, Cpe{
-				CpeURI: uri,
-				UseJVN: true,
-			})
-		}
-		if err := DetectCpeURIsCves(&r, cpes, config.Conf.CveDict, config.Conf.LogOpts); err != nil {
-			return nil, xerrors.Errorf("Failed to detect CVE of `%s`: %w", cpeURIs, err)
-		}
 
-		repos := config.Conf.Servers[r.ServerName].GitHubRepos
-		if err := DetectGitHubCves(&r, repos); err != nil {
-			return nil, xerrors.Errorf("Failed to detect GitHub Cves: %w", err)
-		}
+			repos := config.Conf.Servers[r.ServerName].GitHubRepos
+			if err := DetectGitHubCves(&r, repos); err != nil {
+				return xerrors.Errorf("Failed to detect GitHub Cves: %w", err)
+			}
 
-		if err := DetectWordPressCves(&r, config.Conf.WpScan); err != nil {
-			return nil, xerrors.Errorf("Failed to detect WordPress Cves: %w", err)
-		}
+			if err := DetectWordPressCves(&r, config.Conf.WpScan); err != nil {
+				return xerrors.Errorf("Failed to detect WordPress Cves: %w", err)
+			}
 
-		if err := gost.FillCVEsWithRedHat(&r, config.Conf.Gost, config.Conf.LogOpts); err != nil {
-			return nil, xerrors.Errorf("Failed to fill with gost: %w", err)
-		}
+			if err := gost.FillCVEsWithRedHat(&r, config.Conf.Gost, config.Conf.LogOpts); err != nil {
+				return xerrors.Errorf("Failed to fill with gost: %w", err)
+			}
 
-		if err := FillCvesWithNvdJvn(&r, config.Conf.CveDict, config.Conf.LogOpts); err != nil {
-			return nil, xerrors.Errorf("Failed to fill with CVE: %w", err)
-		}
+			if err := FillCvesWithNvdJvn(&r, clients.cveDict); err != nil {
+				return xerrors.Errorf("Failed to fill with CVE: %w", err)
+			}
 
-		nExploitCve, err := FillWithExploit(&r, config.Conf.Exploit, config.Conf.LogOpts)
-		if err != nil {
-			return nil, xerrors.Errorf("Failed to fill with exploit: %w", err)
-		}
-		logging.Log.Infof("%s: %d PoC are detected", r.FormatServerName(), nExploitCve)
+			vexPaths := []string{}
+			if len(r.Container.ContainerID) == 0 {
+				vexPaths = config.Conf.Servers[r.ServerName].VEXPaths
+			} else if s, ok := config.Conf.Servers[r.ServerName]; ok {
+				if con, ok := s.Containers[r.Container.Name]; ok {
+					vexPaths = con.VEXPaths
+				}
+			}
+			if 0 < len(vexPaths) {
+				if err := DetectWithVEX(&r, vexPaths); err != nil {
+					return xerrors.Errorf("Failed to detect with VEX: %w", err)
+				}
+			}
 
-		nMetasploitCve, err := FillWithMetasploit(&r, config.Conf.Metasploit, config.Conf.LogOpts)
-		if err != nil {
-			return nil, xerrors.Errorf("Failed to fill with metasploit: %w", err)
-		}
-		logging.Log.Infof("%s: %d exploits are detected", r.FormatServerName(), nMetasploitCve)
+			nExploitCve, err := FillWithExploit(&r, config.Conf.Exploit, config.Conf.LogOpts)
+			if err != nil {
+				return xerrors.Errorf("Failed to fill with exploit: %w", err)
+			}
+			logging.Log.Infof("%s: %d PoC are detected", r.FormatServerName(), nExploitCve)
 
-		if err := FillWithKEVuln(&r, config.Conf.KEVuln, config.Conf.LogOpts); err != nil {
-			return nil, xerrors.Errorf("Failed to fill with Known Exploited Vulnerabilities: %w", err)
-		}
+			nMetasploitCve, err := FillWithMetasploit(&r, config.Conf.Metasploit, config.Conf.LogOpts)
+			if err != nil {
+				return xerrors.Errorf("Failed to fill with metasploit: %w", err)
+			}
+			logging.Log.Infof("%s: %d exploits are detected", r.FormatServerName(), nMetasploitCve)
 
-		FillCweDict(&r)
+			if err := FillWithKEVuln(&r, config.Conf.KEVuln, config.Conf.LogOpts); err != nil {
+				return xerrors.Errorf("Failed to fill with Known Exploited Vulnerabilities: %w", err)
+			}
 
-		r.ReportedBy, _ = os.Hostname()
-		r.Lang = config.Conf.Lang
-		r.ReportedAt = reportedAt
-		r.ReportedVersion = config.Version
-		r.ReportedRevision = config.Revision
-		r.Config.Report = config.Conf
-		r.Config.Report.Servers = map[string]config.ServerInfo{
-			r.ServerName: config.Conf.Servers[r.ServerName],
-		}
-		rs[i] = r
+			FillCweDict(&r)
+
+			r.ReportedBy, _ = os.Hostname()
+			r.Lang = config.Conf.Lang
+			r.ReportedAt = reportedAt
+			r.ReportedVersion = config.Version
+			r.ReportedRevision = config.Revision
+			r.Config.Report = config.Conf
+			r.Config.Report.Servers = map[string]config.ServerInfo{
+				r.ServerName: config.Conf.Servers[r.ServerName],
+			}
+			rs[i] = r
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
 	}
 
 	// Overwrite the json file every time to clear the fields specified in config.IgnoredJSONKeys
@@ -163,6 +219,11 @@ func Detect(rs []models.ScanResult, dir string) ([]models.ScanResult, error) {
 			logging.Log.Infof("%s: %d CVEs filtered by --ignore-unfixed", r.FormatServerName(), nFiltered)
 		}
 
+		if 0 < len(config.Conf.IgnoreStatuses) {
+			r.ScannedCves, nFiltered = r.ScannedCves.FilterByStatuses(config.Conf.IgnoreStatuses)
+			logging.Log.Infof("%s: %d CVEs filtered by --ignore-status=%s", r.FormatServerName(), nFiltered, config.Conf.IgnoreStatuses)
+		}
+
 		if 0 < config.Conf.ConfidenceScoreOver {
 			r.ScannedCves, nFiltered = r.ScannedCves.FilterByConfidenceOver(config.Conf.ConfidenceScoreOver)
 			logging.Log.Infof("%s: %d CVEs filtered by --confidence-over=%d", r.FormatServerName(), nFiltered, config.Conf.ConfidenceScoreOver)
@@ -205,8 +266,7 @@ func Detect(rs []models.ScanResult, dir string) ([]models.ScanResult, error) {
 }
 
 // DetectPkgCves detects OS pkg cves
-// pass 2 configs
-func DetectPkgCves(r *models.ScanResult, ovalCnf config.GovalDictConf, gostCnf config.GostConf, logOpts logging.LogOpts) error {
+func DetectPkgCves(r *models.ScanResult, clients *detectClients) error {
 	// Pkg Scan
 	if isPkgCvesDetactable(r) {
 		// OVAL, gost(Debian Security Tracker) does not support Package for Raspbian, so skip it.
@@ -215,12 +275,12 @@ func DetectPkgCves(r *models.ScanResult, ovalCnf config.GovalDictConf, gostCnf c
 		}
 
 		// OVAL
-		if err := detectPkgsCvesWithOval(ovalCnf, r, logOpts); err != nil {
+		if err := detectPkgsCvesWithOval(r, clients); err != nil {
 			return xerrors.Errorf("Failed to detect CVE with OVAL: %w", err)
 		}
 
 		// gost
-		if err := detectPkgsCvesWithGost(gostCnf, r, logOpts); err != nil {
+		if err := detectPkgsCvesWithGost(r, clients); err != nil {
 			return xerrors.Errorf("Failed to detect CVE with gost: %w", err)
 		}
 	}
@@ -231,6 +291,15 @@ func DetectPkgCves(r *models.ScanResult, ovalCnf config.GovalDictConf, gostCnf c
 				p.FixState = "Not fixed yet"
 				r.ScannedCves[i].AffectedPackages[j] = p
 			}
+			// detectPkgsCvesWithGost/Oval set Status from structured data
+			// where they can. It is still empty here for results loaded from
+			// older JSON, which predates Status, and for gost findings whose
+			// FixState gostFixStateToStatus doesn't recognise, so fall back
+			// to guessing from the free-form FixState string for those.
+			if p.Status == "" {
+				p.Status = models.NewPackageFixStatus(p.FixState)
+				r.ScannedCves[i].AffectedPackages[j] = p
+			}
 		}
 	}
 
@@ -316,22 +385,12 @@ func DetectWordPressCves(r *models.ScanResult, wpCnf config.WpScanConf) error {
 }
 
 // FillCvesWithNvdJvn fills CVE detail with NVD, JVN
-func FillCvesWithNvdJvn(r *models.ScanResult, cnf config.GoCveDictConf, logOpts logging.LogOpts) (err error) {
+func FillCvesWithNvdJvn(r *models.ScanResult, client *goCveDictClient) (err error) {
 	cveIDs := []string{}
 	for _, v := range r.ScannedCves {
 		cveIDs = append(cveIDs, v.CveID)
 	}
 
-	client, err := newGoCveDictClient(&cnf, logOpts)
-	if err != nil {
-		return xerrors.Errorf("Failed to newGoCveDictClient. err: %w", err)
-	}
-	defer func() {
-		if err := client.closeDB(); err != nil {
-			logging.Log.Errorf("Failed to close DB. err: %+v", err)
-		}
-	}()
-
 	ds, err := client.fetchCveDetails(cveIDs)
 	if err != nil {
 		return xerrors.Errorf("Failed to fetchCveDetails. err: %w", err)
@@ -402,16 +461,11 @@ func fillCertAlerts(cvedetail *cvemodels.CveDetail) (dict models.AlertDict) {
 }
 
 // detectPkgsCvesWithOval fetches OVAL database
-func detectPkgsCvesWithOval(cnf config.GovalDictConf, r *models.ScanResult, logOpts logging.LogOpts) error {
-	client, err := oval.NewOVALClient(r.Family, cnf, logOpts)
+func detectPkgsCvesWithOval(r *models.ScanResult, clients *detectClients) error {
+	client, err := clients.oval(r.Family)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if err := client.CloseDB(); err != nil {
-			logging.Log.Errorf("Failed to close the OVAL DB. err: %+v", err)
-		}
-	}()
 
 	logging.Log.Debugf("Check if oval fetched: %s %s", r.Family, r.Release)
 	ok, err := client.CheckIfOvalFetched(r.Family, r.Release)
@@ -443,20 +497,32 @@ func detectPkgsCvesWithOval(cnf config.GovalDictConf, r *models.ScanResult, logO
 		return err
 	}
 
+	// An OVAL definition matching the installed package is itself the
+	// structured signal: the package is affected, full stop. OVAL doesn't
+	// carry the richer wontfix/deferred/under-investigation vocabulary gost
+	// does, so only fill Status in where FillWithOval left it unset.
+	for i, v := range r.ScannedCves {
+		for j, p := range v.AffectedPackages {
+			if p.Status == "" {
+				p.Status = models.StatusAffected
+				r.ScannedCves[i].AffectedPackages[j] = p
+			}
+		}
+	}
+
 	logging.Log.Infof("%s: %d CVEs are detected with OVAL", r.FormatServerName(), nCVEs)
 	return nil
 }
 
-func detectPkgsCvesWithGost(cnf config.GostConf, r *models.ScanResult, logOpts logging.LogOpts) error {
-	client, err := gost.NewGostClient(cnf, r.Family, logOpts)
+// detectPkgsCvesWithGost fetches gost DB and fills AffectedPackages[].Status
+// with the full Red Hat/Ubuntu vocabulary (affected, fixed, not_affected,
+// under_investigation, will_not_fix, fix_deferred, end_of_life, unknown)
+// instead of leaving it to the legacy free-form FixState string.
+func detectPkgsCvesWithGost(r *models.ScanResult, clients *detectClients) error {
+	client, err := clients.gost(r.Family)
 	if err != nil {
 		return xerrors.Errorf("Failed to new a gost client: %w", err)
 	}
-	defer func() {
-		if err := client.CloseDB(); err != nil {
-			logging.Log.Errorf("Failed to close the gost DB. err: %+v", err)
-		}
-	}()
 
 	nCVEs, err := client.DetectCVEs(r, true)
 	if err != nil {
@@ -466,6 +532,15 @@ func detectPkgsCvesWithGost(cnf config.GostConf, r *models.ScanResult, logOpts l
 		return xerrors.Errorf("Failed to detect unfixed CVEs with gost: %w", err)
 	}
 
+	for i, v := range r.ScannedCves {
+		for j, p := range v.AffectedPackages {
+			if status, ok := gostFixStateToStatus(p.FixState); ok {
+				p.Status = status
+				r.ScannedCves[i].AffectedPackages[j] = p
+			}
+		}
+	}
+
 	if r.Family == constant.Debian {
 		logging.Log.Infof("%s: %d CVEs are detected with gost",
 			r.FormatServerName(), nCVEs)
@@ -476,18 +551,34 @@ func detectPkgsCvesWithGost(cnf config.GostConf, r *models.ScanResult, logOpts l
 	return nil
 }
 
-// DetectCpeURIsCves detects CVEs of given CPE-URIs
-func DetectCpeURIsCves(r *models.ScanResult, cpes []Cpe, cnf config.GoCveDictConf, logOpts logging.LogOpts) error {
-	client, err := newGoCveDictClient(&cnf, logOpts)
-	if err != nil {
-		return xerrors.Errorf("Failed to newGoCveDictClient. err: %w", err)
+// gostFixStateToStatus maps gost's free-form Red Hat/Ubuntu FixState string
+// to the normalized PackageFixStatus vocabulary, so --ignore-status can act
+// on structured data instead of the raw string. ok is false for FixState
+// values gost hasn't been observed to emit, leaving Status for the
+// legacy-migration fallback in DetectPkgCves to fill in.
+func gostFixStateToStatus(fixState string) (status models.PackageFixStatus, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(fixState)) {
+	case "affected":
+		return models.StatusAffected, true
+	case "fixed", "released":
+		return models.StatusFixed, true
+	case "not affected", "not_affected":
+		return models.StatusNotAffected, true
+	case "under investigation", "under_investigation":
+		return models.StatusUnderInvestigation, true
+	case "will not fix", "wontfix", "will_not_fix":
+		return models.StatusWillNotFix, true
+	case "deferred", "fix deferred", "fix_deferred":
+		return models.StatusFixDeferred, true
+	case "out of support scope", "end of life", "end_of_life":
+		return models.StatusEndOfLife, true
+	default:
+		return "", false
 	}
-	defer func() {
-		if err := client.closeDB(); err != nil {
-			logging.Log.Errorf("Failed to close DB. err: %+v", err)
-		}
-	}()
+}
 
+// DetectCpeURIsCves detects CVEs of given CPE-URIs
+func DetectCpeURIsCves(r *models.ScanResult, cpes []Cpe, client *goCveDictClient) error {
 	nCVEs := 0
 	for _, cpe := range cpes {
 		details, err := client.detectCveByCpeURI(cpe.CpeURI, cpe.UseJVN)
@@ -0,0 +1,36 @@
+//go:build !scanner
+// +build !scanner
+
+package detector
+
+import (
+	"testing"
+
+	"github.com/future-architect/vuls/models"
+)
+
+func TestGostFixStateToStatus(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   models.PackageFixStatus
+		wantOk bool
+	}{
+		{"affected", models.StatusAffected, true},
+		{"Fixed", models.StatusFixed, true},
+		{"released", models.StatusFixed, true},
+		{"not affected", models.StatusNotAffected, true},
+		{"under investigation", models.StatusUnderInvestigation, true},
+		{"wontfix", models.StatusWillNotFix, true},
+		{"will not fix", models.StatusWillNotFix, true},
+		{"fix deferred", models.StatusFixDeferred, true},
+		{"out of support scope", models.StatusEndOfLife, true},
+		{"Not fixed yet", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := gostFixStateToStatus(tt.in)
+		if ok != tt.wantOk || got != tt.want {
+			t.Errorf("gostFixStateToStatus(%q) = (%q, %v), want (%q, %v)", tt.in, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+//go:build !scanner
+// +build !scanner
+
+package detector
+
+import (
+	"debug/buildinfo"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/xerrors"
+
+	"github.com/future-architect/vuls/logging"
+	"github.com/future-architect/vuls/models"
+)
+
+// goVulnDBIndexURL is the Go vulnerability database's module index,
+// documented at https://vuln.go.dev/docs/#api. It only maps a module path
+// to the IDs of advisories affecting it - the fixed version and vulnerable
+// symbols for each advisory live in that advisory's own per-ID document, so
+// DetectGoBinaryCves fetches index/db.json once and then fetches only the
+// documents for advisories whose module actually shows up in a scanned
+// binary, instead of pulling every advisory up front.
+//
+// Declared as a var, not a const, so tests can point it at an httptest
+// server instead of the real feed.
+var goVulnDBIndexURL = "https://vuln.go.dev/index/db.json"
+
+// goVulnDBEntryURLFmt is the per-advisory document endpoint; %s is the
+// advisory ID (e.g. "GO-2023-1495"). A var for the same reason as
+// goVulnDBIndexURL.
+var goVulnDBEntryURLFmt = "https://vuln.go.dev/ID/%s.json"
+
+// goVulnDBIndexCacheFile is where the fetched module index is cached under
+// TrivyCacheDBDir, so repeat scans don't re-fetch it.
+const goVulnDBIndexCacheFile = "govulndb-index.json"
+
+// goVulnDBEntryCacheDir holds one cached per-advisory document per ID,
+// alongside goVulnDBIndexCacheFile under TrivyCacheDBDir.
+const goVulnDBEntryCacheDir = "govulndb-entries"
+
+// goVulnDBIndex is the shape of index/db.json: a module path mapped to the
+// advisories affecting it.
+type goVulnDBIndex map[string][]goIndexEntry
+
+type goIndexEntry struct {
+	ID string `json:"id"`
+}
+
+// goAdvisoryDoc is the subset of a Go vulnerability database per-ID document
+// (https://vuln.go.dev/ID/GO-YYYY-NNNN.json, itself an OSV entry)
+// DetectGoBinaryCves needs: the modules it affects and, per affected import
+// path, the vulnerable symbols.
+type goAdvisoryDoc struct {
+	ID       string               `json:"id"`
+	Affected []goAdvisoryAffected `json:"affected"`
+}
+
+type goAdvisoryAffected struct {
+	Package           goAdvisoryPackage           `json:"package"`
+	Ranges            []goAdvisoryRange           `json:"ranges"`
+	EcosystemSpecific goAdvisoryEcosystemSpecific `json:"ecosystem_specific"`
+}
+
+type goAdvisoryPackage struct {
+	Path string `json:"path"`
+}
+
+type goAdvisoryRange struct {
+	Type   string                 `json:"type"`
+	Events []goAdvisoryRangeEvent `json:"events"`
+}
+
+type goAdvisoryRangeEvent struct {
+	Introduced string `json:"introduced,omitempty"`
+	Fixed      string `json:"fixed,omitempty"`
+}
+
+type goAdvisoryEcosystemSpecific struct {
+	Imports []goAdvisoryImport `json:"imports"`
+}
+
+type goAdvisoryImport struct {
+	Path    string   `json:"path"`
+	Symbols []string `json:"symbols"`
+}
+
+// DetectGoBinaryCves inspects every Go binary vuls's library scanner found
+// and determines, per the Go vulnerability database, whether the binary only
+// has a vulnerable module version (GoModuleVersionMatch) or whether a
+// vulnerable symbol is actually reachable in the compiled binary
+// (GoSymbolReachable), so reports can highlight the truly exploitable subset.
+func DetectGoBinaryCves(r *models.ScanResult, trivyCacheDBDir string) error {
+	index, err := loadGoVulnDBIndex(trivyCacheDBDir)
+	if err != nil {
+		return xerrors.Errorf("Failed to load Go vulndb index: %w", err)
+	}
+	if len(index) == 0 {
+		return nil
+	}
+
+	docCache := map[string]*goAdvisoryDoc{}
+	nCVEs := 0
+	for _, scanner := range r.LibraryScanners {
+		if scanner.Type != models.Gomod || scanner.Path == "" {
+			continue
+		}
+
+		info, err := buildinfo.ReadFile(scanner.Path)
+		if err != nil {
+			// Not every gomod scanner entry points at a binary; skip source
+			// trees and modules without embedded build info.
+			continue
+		}
+
+		symbols, stripped := readSymbolTable(scanner.Path)
+
+		for path, version := range goBinaryModules(info) {
+			for _, entry := range index[path] {
+				doc, err := loadGoAdvisoryDoc(trivyCacheDBDir, entry.ID, docCache)
+				if err != nil {
+					logging.Log.Warnf("%s: failed to load Go vulndb advisory %s: %v", r.FormatServerName(), entry.ID, err)
+					continue
+				}
+
+				for _, aff := range doc.Affected {
+					if aff.Package.Path != path {
+						continue
+					}
+					if !goVersionAffected(version, goAdvisoryFixedVersion(aff)) {
+						continue
+					}
+
+					confidence := models.GoModuleVersionMatch
+					if !stripped && goSymbolReachable(symbols, aff.EcosystemSpecific.Imports) {
+						confidence = models.GoSymbolReachable
+					} else if stripped {
+						logging.Log.Warnf("%s: %s is stripped, falling back to module-level match for %s", r.FormatServerName(), scanner.Path, doc.ID)
+					}
+
+					vinfo, ok := r.ScannedCves[doc.ID]
+					if !ok {
+						vinfo = models.VulnInfo{CveID: doc.ID}
+					}
+					vinfo.Confidences.AppendIfMissing(confidence)
+					r.ScannedCves[doc.ID] = vinfo
+					nCVEs++
+				}
+			}
+		}
+	}
+	logging.Log.Infof("%s: %d CVEs are detected in Go binaries", r.FormatServerName(), nCVEs)
+	return nil
+}
+
+// goBinaryModules returns every module path built into the binary, main
+// module included, mapped to its recorded version.
+func goBinaryModules(info *buildinfo.BuildInfo) map[string]string {
+	modules := make(map[string]string, len(info.Deps)+1)
+	modules[info.Main.Path] = info.Main.Version
+	for _, dep := range info.Deps {
+		modules[dep.Path] = dep.Version
+	}
+	return modules
+}
+
+// goAdvisoryFixedVersion returns the first fixed version an advisory's
+// ranges list for the affected module, or "" if the advisory has no known fix.
+func goAdvisoryFixedVersion(aff goAdvisoryAffected) string {
+	for _, rng := range aff.Ranges {
+		for _, ev := range rng.Events {
+			if ev.Fixed != "" {
+				return ev.Fixed
+			}
+		}
+	}
+	return ""
+}
+
+// goVersionAffected reports whether installed is before fixed. Locally-built
+// binaries commonly report "(devel)" (or, rarely, an empty string) instead
+// of a real module version, which isn't valid semver and can't be meaningfully
+// compared, so those are treated as not matching rather than passed to
+// CompareVersion.
+func goVersionAffected(installed, fixed string) bool {
+	if installed == "" || installed == "(devel)" {
+		return false
+	}
+	return fixed == "" || models.CompareVersion(models.Gomod, installed, fixed) < 0
+}
+
+// readSymbolTable reads path's symbol table directly from its object file
+// format (ELF, Mach-O, or PE - whichever Go toolchain produced it) and
+// returns the symbol names goFuncSymbolNames finds in it, along with
+// whether the binary turned out to be stripped (no symbol table at all).
+// Go binaries built with default settings keep this table even when not
+// built with -ldflags=-s, so this covers the common case; govulncheck's own
+// stack-based reachability analysis is out of scope here.
+func readSymbolTable(path string) (map[string]bool, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, true
+	}
+	defer f.Close()
+
+	names, err := goFuncSymbolNames(f)
+	if err != nil || len(names) == 0 {
+		return nil, true
+	}
+
+	found := make(map[string]bool, len(names))
+	for _, s := range names {
+		found[s] = true
+	}
+	return found, false
+}
+
+// goFuncSymbolNames extracts symbol names from a binary, trying each object
+// file format vuls's supported platforms produce it in. For ELF only
+// function (STT_FUNC) symbols are returned; for Mach-O and PE every symbol
+// in the table is returned, data symbols included.
+func goFuncSymbolNames(f *os.File) ([]string, error) {
+	if ef, err := elf.NewFile(f); err == nil {
+		syms, err := ef.Symbols()
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(syms))
+		for _, s := range syms {
+			if elf.ST_TYPE(s.Info) == elf.STT_FUNC {
+				names = append(names, s.Name)
+			}
+		}
+		return names, nil
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if mf, err := macho.NewFile(f); err == nil {
+		if mf.Symtab == nil {
+			return nil, nil
+		}
+		names := make([]string, 0, len(mf.Symtab.Syms))
+		for _, s := range mf.Symtab.Syms {
+			names = append(names, s.Name)
+		}
+		return names, nil
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if pf, err := pe.NewFile(f); err == nil {
+		names := make([]string, 0, len(pf.Symbols))
+		for _, s := range pf.Symbols {
+			names = append(names, s.Name)
+		}
+		return names, nil
+	}
+
+	return nil, xerrors.Errorf("%s is not a recognized ELF, Mach-O, or PE binary", f.Name())
+}
+
+// goSymbolReachable reports whether any symbol in advisory's affected
+// imports is present in the binary's symbol table.
+func goSymbolReachable(symbols map[string]bool, imports []goAdvisoryImport) bool {
+	for _, imp := range imports {
+		for _, sym := range imp.Symbols {
+			if symbols[imp.Path+"."+sym] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// loadGoVulnDBIndex returns the Go vulnerability database's module index,
+// fetching and caching it under trivyCacheDBDir on first use and reusing the
+// cache on every call after that, the same way DetectLibsCves reuses its
+// trivy-db cache instead of re-fetching per scan.
+func loadGoVulnDBIndex(trivyCacheDBDir string) (goVulnDBIndex, error) {
+	if trivyCacheDBDir == "" {
+		return nil, nil
+	}
+
+	cachePath := filepath.Join(trivyCacheDBDir, goVulnDBIndexCacheFile)
+	data, err := os.ReadFile(cachePath)
+	switch {
+	case err == nil:
+		// cached
+	case os.IsNotExist(err):
+		data, err = fetchGoVulnDB(goVulnDBIndexURL)
+		if err != nil {
+			return nil, xerrors.Errorf("Failed to fetch Go vulndb index: %w", err)
+		}
+		if err := os.MkdirAll(trivyCacheDBDir, 0700); err != nil {
+			return nil, xerrors.Errorf("Failed to create %s: %w", trivyCacheDBDir, err)
+		}
+		if err := os.WriteFile(cachePath, data, 0600); err != nil {
+			return nil, xerrors.Errorf("Failed to cache Go vulndb index to %s: %w", cachePath, err)
+		}
+	default:
+		return nil, xerrors.Errorf("Failed to read %s: %w", cachePath, err)
+	}
+
+	var index goVulnDBIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, xerrors.Errorf("Failed to parse Go vulndb index: %w", err)
+	}
+	return index, nil
+}
+
+// loadGoAdvisoryDoc returns the per-ID document for a Go vulndb advisory,
+// checking cache (an in-process map so one scan doesn't fetch the same
+// advisory twice across several binaries) before an on-disk cache under
+// trivyCacheDBDir, and only falling back to fetchGoVulnDB when neither has
+// it yet.
+func loadGoAdvisoryDoc(trivyCacheDBDir, id string, cache map[string]*goAdvisoryDoc) (*goAdvisoryDoc, error) {
+	if doc, ok := cache[id]; ok {
+		return doc, nil
+	}
+
+	dir := filepath.Join(trivyCacheDBDir, goVulnDBEntryCacheDir)
+	cachePath := filepath.Join(dir, id+".json")
+	data, err := os.ReadFile(cachePath)
+	switch {
+	case err == nil:
+		// cached
+	case os.IsNotExist(err):
+		data, err = fetchGoVulnDB(fmt.Sprintf(goVulnDBEntryURLFmt, id))
+		if err != nil {
+			return nil, xerrors.Errorf("Failed to fetch Go vulndb advisory %s: %w", id, err)
+		}
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, xerrors.Errorf("Failed to create %s: %w", dir, err)
+		}
+		if err := os.WriteFile(cachePath, data, 0600); err != nil {
+			return nil, xerrors.Errorf("Failed to cache Go vulndb advisory %s to %s: %w", id, cachePath, err)
+		}
+	default:
+		return nil, xerrors.Errorf("Failed to read %s: %w", cachePath, err)
+	}
+
+	var doc goAdvisoryDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, xerrors.Errorf("Failed to parse Go vulndb advisory %s: %w", id, err)
+	}
+	cache[id] = &doc
+	return &doc, nil
+}
+
+// fetchGoVulnDB downloads and returns the body of a Go vulnerability
+// database URL - either the module index or a single advisory document.
+func fetchGoVulnDB(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, xerrors.Errorf("Failed to fetch %s: status %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to read response from %s: %w", url, err)
+	}
+	return body, nil
+}
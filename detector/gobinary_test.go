@@ -0,0 +1,210 @@
+//go:build !scanner
+// +build !scanner
+
+package detector
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoVersionAffected(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed string
+		fixed     string
+		want      bool
+	}{
+		{"devel build is never matched", "(devel)", "1.2.0", false},
+		{"empty version is never matched", "", "1.2.0", false},
+		{"below fixed version", "1.1.0", "1.2.0", true},
+		{"at fixed version", "1.2.0", "1.2.0", false},
+		{"no fixed version means always affected", "1.1.0", "", true},
+	}
+	for _, tt := range tests {
+		if got := goVersionAffected(tt.installed, tt.fixed); got != tt.want {
+			t.Errorf("%s: goVersionAffected(%q, %q) = %v, want %v", tt.name, tt.installed, tt.fixed, got, tt.want)
+		}
+	}
+}
+
+func TestGoSymbolReachable(t *testing.T) {
+	symbols := map[string]bool{
+		"github.com/example/lib.Vulnerable": true,
+	}
+	imports := []goAdvisoryImport{
+		{Path: "github.com/example/lib", Symbols: []string{"Safe", "Vulnerable"}},
+	}
+	if !goSymbolReachable(symbols, imports) {
+		t.Errorf("expected Vulnerable symbol to be reachable")
+	}
+
+	if goSymbolReachable(map[string]bool{}, imports) {
+		t.Errorf("expected no symbols to be reachable against an empty symbol table")
+	}
+}
+
+func TestGoFuncSymbolNames(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(src, []byte("package main\nfunc main() {}\n"), 0600); err != nil {
+		t.Fatalf("Failed to write test source: %v", err)
+	}
+
+	bin := filepath.Join(dir, "bin")
+	cmd := exec.Command(goBin, "build", "-o", bin, src)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("Failed to build test binary: %v: %s", err, out)
+	}
+
+	f, err := os.Open(bin)
+	if err != nil {
+		t.Fatalf("Failed to open built binary: %v", err)
+	}
+	defer f.Close()
+
+	names, err := goFuncSymbolNames(f)
+	if err != nil {
+		t.Fatalf("goFuncSymbolNames returned error: %v", err)
+	}
+	if len(names) == 0 {
+		t.Fatalf("expected a non-empty symbol table from a freshly built, unstripped binary")
+	}
+
+	found := false
+	for _, n := range names {
+		if strings.Contains(n, "main.main") {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("expected main.main among the function symbols, got %v", names)
+	}
+}
+
+func TestGoFuncSymbolNamesRejectsNonBinary(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "not-a-binary")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString("not an object file"); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatalf("Failed to seek temp file: %v", err)
+	}
+
+	if _, err := goFuncSymbolNames(f); err == nil {
+		t.Errorf("expected an error for a file that isn't ELF, Mach-O, or PE")
+	}
+}
+
+func TestFetchGoVulnDB(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	body, err := fetchGoVulnDB(srv.URL)
+	if err != nil {
+		t.Fatalf("fetchGoVulnDB returned error: %v", err)
+	}
+	if string(body) != `{"ok":true}` {
+		t.Errorf("got body %q, want %q", body, `{"ok":true}`)
+	}
+}
+
+func TestFetchGoVulnDBNon200(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	if _, err := fetchGoVulnDB(srv.URL); err == nil {
+		t.Errorf("expected an error for a non-200 response")
+	}
+}
+
+func TestLoadGoVulnDBIndexFetchesThenCaches(t *testing.T) {
+	nRequests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nRequests++
+		_ = json.NewEncoder(w).Encode(goVulnDBIndex{
+			"github.com/example/lib": []goIndexEntry{{ID: "GO-2024-0001"}},
+		})
+	}))
+	defer srv.Close()
+
+	origURL := goVulnDBIndexURL
+	goVulnDBIndexURL = srv.URL
+	defer func() { goVulnDBIndexURL = origURL }()
+
+	cacheDir := t.TempDir()
+
+	for i := 0; i < 2; i++ {
+		index, err := loadGoVulnDBIndex(cacheDir)
+		if err != nil {
+			t.Fatalf("loadGoVulnDBIndex returned error: %v", err)
+		}
+		if len(index["github.com/example/lib"]) != 1 || index["github.com/example/lib"][0].ID != "GO-2024-0001" {
+			t.Fatalf("got index %v, want one entry for github.com/example/lib with ID GO-2024-0001", index)
+		}
+	}
+
+	if nRequests != 1 {
+		t.Errorf("expected the index to be fetched once and served from cache after, got %d requests", nRequests)
+	}
+}
+
+func TestLoadGoAdvisoryDocFetchesThenCaches(t *testing.T) {
+	nRequests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nRequests++
+		_ = json.NewEncoder(w).Encode(goAdvisoryDoc{
+			ID: "GO-2024-0001",
+			Affected: []goAdvisoryAffected{{
+				Package: goAdvisoryPackage{Path: "github.com/example/lib"},
+				Ranges:  []goAdvisoryRange{{Type: "SEMVER", Events: []goAdvisoryRangeEvent{{Fixed: "1.2.0"}}}},
+			}},
+		})
+	}))
+	defer srv.Close()
+
+	origFmt := goVulnDBEntryURLFmt
+	goVulnDBEntryURLFmt = srv.URL + "/%s"
+	defer func() { goVulnDBEntryURLFmt = origFmt }()
+
+	cacheDir := t.TempDir()
+	cache := map[string]*goAdvisoryDoc{}
+
+	for i := 0; i < 2; i++ {
+		doc, err := loadGoAdvisoryDoc(cacheDir, "GO-2024-0001", cache)
+		if err != nil {
+			t.Fatalf("loadGoAdvisoryDoc returned error: %v", err)
+		}
+		if doc.ID != "GO-2024-0001" || goAdvisoryFixedVersion(doc.Affected[0]) != "1.2.0" {
+			t.Fatalf("got doc %+v, want ID GO-2024-0001 with fixed version 1.2.0", doc)
+		}
+		// Force the on-disk cache to be consulted on the second iteration by
+		// dropping the in-process cache.
+		delete(cache, "GO-2024-0001")
+	}
+
+	if nRequests != 1 {
+		t.Errorf("expected the advisory doc to be fetched once and served from cache after, got %d requests", nRequests)
+	}
+}
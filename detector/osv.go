@@ -0,0 +1,310 @@
+//go:build !scanner
+// +build !scanner
+
+package detector
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/future-architect/vuls/config"
+	"github.com/future-architect/vuls/logging"
+	"github.com/future-architect/vuls/models"
+)
+
+// osvEcosystems maps the ecosystem names OSV feeds use to the library
+// ecosystems vuls already scans with DetectLibsCves, so results merge on
+// the same VulnInfo when aliases match.
+var osvEcosystems = map[string]models.LibraryType{
+	"Go":        models.Gomod,
+	"npm":       models.NPM,
+	"PyPI":      models.Pip,
+	"RubyGems":  models.Bundler,
+	"Maven":     models.Maven,
+	"NuGet":     models.Nuget,
+	"crates.io": models.Cargo,
+	"Packagist": models.Composer,
+}
+
+// osvEntry is the subset of the OSV schema (https://ossf.github.io/osv-schema/)
+// vuls needs to match installed packages and fill in a VulnInfo.
+type osvEntry struct {
+	ID         string        `json:"id"`
+	Aliases    []string      `json:"aliases"`
+	Summary    string        `json:"summary"`
+	References []osvRef      `json:"references"`
+	Severity   []osvSeverity `json:"severity"`
+	Affected   []osvAffected `json:"affected"`
+}
+
+type osvRef struct {
+	URL string `json:"url"`
+}
+
+type osvSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type osvAffected struct {
+	Package  osvPackage `json:"package"`
+	Ranges   []osvRange `json:"ranges"`
+	Versions []string   `json:"versions"`
+}
+
+type osvPackage struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+type osvRange struct {
+	Type   string          `json:"type"` // SEMVER, ECOSYSTEM, or GIT
+	Events []osvRangeEvent `json:"events"`
+}
+
+type osvRangeEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+}
+
+// DetectPkgCvesWithOSV matches the libraries vuls already scanned against an
+// OSV-schema feed (osv.dev exports, GHSA OSV dumps, PyPA/RustSec, ...)
+// loaded from a local directory or HTTP endpoint, keying on
+// ecosystem + package name + installed version.
+func DetectPkgCvesWithOSV(r *models.ScanResult, cnf config.OSVConf, logOpts logging.LogOpts) error {
+	if cnf.Path == "" && cnf.URL == "" {
+		return nil
+	}
+
+	entries, err := loadOSVEntries(cnf)
+	if err != nil {
+		return xerrors.Errorf("Failed to load OSV feed: %w", err)
+	}
+
+	nCVEs := 0
+	for _, scanner := range r.LibraryScanners {
+		ecosystem, ok := osvEcosystemOf(scanner.Type)
+		if !ok {
+			continue
+		}
+		for _, lib := range scanner.Libs {
+			for _, e := range entries {
+				for _, affected := range e.Affected {
+					if affected.Package.Ecosystem != ecosystem || affected.Package.Name != lib.Name {
+						continue
+					}
+					if !osvVersionAffected(scanner.Type, lib.Version, affected) {
+						continue
+					}
+					if mergeOSVIntoScannedCves(r, e, lib, affected) {
+						continue
+					}
+					id := primaryCveID(e)
+					r.ScannedCves[id] = models.VulnInfo{
+						CveID:       id,
+						Confidences: models.Confidences{models.OSVMatch},
+						CveContents: osvToCveContents(e),
+						LibraryFixedIns: []models.LibraryFixedIn{{
+							Key:     "osv",
+							Name:    lib.Name,
+							FixedIn: osvFixedIn(affected),
+						}},
+					}
+					nCVEs++
+				}
+			}
+		}
+	}
+	logging.Log.Infof("%s: %d CVEs are detected with OSV", r.FormatServerName(), nCVEs)
+	return nil
+}
+
+// osvEcosystemOf returns the OSV ecosystem name (e.g. "Go", "npm") a vuls
+// LibraryType maps to, the inverse of osvEcosystems, so adding a new
+// ecosystem only touches that one map.
+func osvEcosystemOf(t models.LibraryType) (string, bool) {
+	for name, lt := range osvEcosystems {
+		if lt == t {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// mergeOSVIntoScannedCves folds an OSV match into an already-detected CVE
+// when one of e.Aliases matches a CVE ID DetectLibsCves or gost/OVAL already
+// found, instead of creating a duplicate VulnInfo for the same finding. It
+// still attaches OSV's own CveContents and LibraryFixedIn, the same detail a
+// brand-new VulnInfo would get, so the CVE-aliased case - the common one -
+// doesn't lose the OSV summary/CVSS/fixed-version data.
+func mergeOSVIntoScannedCves(r *models.ScanResult, e osvEntry, lib models.Library, affected osvAffected) bool {
+	for _, alias := range e.Aliases {
+		if !strings.HasPrefix(alias, "CVE-") {
+			continue
+		}
+		vinfo, ok := r.ScannedCves[alias]
+		if !ok {
+			continue
+		}
+		vinfo.Confidences.AppendIfMissing(models.OSVMatch)
+		if vinfo.CveContents == nil {
+			vinfo.CveContents = models.CveContents{}
+		}
+		for typ, conts := range osvToCveContents(e) {
+			vinfo.CveContents[typ] = conts
+		}
+		vinfo.LibraryFixedIns = append(vinfo.LibraryFixedIns, models.LibraryFixedIn{
+			Key:     "osv",
+			Name:    lib.Name,
+			FixedIn: osvFixedIn(affected),
+		})
+		r.ScannedCves[alias] = vinfo
+		return true
+	}
+	return false
+}
+
+// primaryCveID prefers a CVE alias so downstream CVE-keyed reporting keeps
+// working; falls back to the OSV ID itself (e.g. GHSA-only advisories).
+func primaryCveID(e osvEntry) string {
+	for _, alias := range e.Aliases {
+		if strings.HasPrefix(alias, "CVE-") {
+			return alias
+		}
+	}
+	return e.ID
+}
+
+// osvToCveContents converts an OSV entry's summary/severity/references into
+// a models.CveContents keyed under a dedicated OSV content type, the same
+// way ConvertNvdToModel/ConvertJvnToModel adapt their upstream formats.
+func osvToCveContents(e osvEntry) models.CveContents {
+	refs := make([]models.Reference, 0, len(e.References))
+	for _, ref := range e.References {
+		refs = append(refs, models.Reference{Source: "OSV", Link: ref.URL})
+	}
+
+	content := models.CveContent{
+		Type:       models.OSV,
+		CveID:      e.ID,
+		Summary:    e.Summary,
+		References: refs,
+		SourceLink: "https://osv.dev/vulnerability/" + e.ID,
+	}
+	for _, sev := range e.Severity {
+		if sev.Type == "CVSS_V3" {
+			content.Cvss3Severity = sev.Score
+		}
+	}
+	return models.CveContents{models.OSV: []models.CveContent{content}}
+}
+
+func osvFixedIn(affected osvAffected) string {
+	for _, rng := range affected.Ranges {
+		for _, ev := range rng.Events {
+			if ev.Fixed != "" {
+				return ev.Fixed
+			}
+		}
+	}
+	return ""
+}
+
+// osvVersionAffected applies affected[].versions and the SEMVER and ECOSYSTEM
+// entries of affected[].ranges using the per-family version comparator
+// models already exposes for the library's ecosystem. GIT ranges are skipped,
+// and within one range only the last introduced, fixed and last_affected
+// events are used.
+func osvVersionAffected(libType models.LibraryType, installed string, affected osvAffected) bool {
+	for _, v := range affected.Versions {
+		if v == installed {
+			return true
+		}
+	}
+
+	for _, rng := range affected.Ranges {
+		if rng.Type == "GIT" {
+			// Commit-pinned ranges can't be compared against a semantic
+			// installed version; leave matching to the Versions list above.
+			continue
+		}
+
+		introduced, fixed, lastAffected := "0", "", ""
+		for _, ev := range rng.Events {
+			switch {
+			case ev.Introduced != "":
+				introduced = ev.Introduced
+			case ev.Fixed != "":
+				fixed = ev.Fixed
+			case ev.LastAffected != "":
+				lastAffected = ev.LastAffected
+			}
+		}
+
+		if models.CompareVersion(libType, installed, introduced) < 0 {
+			continue
+		}
+		if fixed != "" && models.CompareVersion(libType, installed, fixed) >= 0 {
+			continue
+		}
+		if lastAffected != "" && models.CompareVersion(libType, installed, lastAffected) > 0 {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func loadOSVEntries(cnf config.OSVConf) ([]osvEntry, error) {
+	entries := []osvEntry{}
+
+	if cnf.URL != "" {
+		resp, err := http.Get(cnf.URL)
+		if err != nil {
+			return nil, xerrors.Errorf("Failed to fetch OSV feed %s: %w", cnf.URL, err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, xerrors.Errorf("Failed to read OSV feed %s: %w", cnf.URL, err)
+		}
+		var e osvEntry
+		if err := json.Unmarshal(body, &e); err != nil {
+			return nil, xerrors.Errorf("Failed to parse OSV feed %s: %w", cnf.URL, err)
+		}
+		entries = append(entries, e)
+	}
+
+	if cnf.Path != "" {
+		err := filepath.Walk(cnf.Path, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || filepath.Ext(path) != ".json" {
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return xerrors.Errorf("Failed to read %s: %w", path, err)
+			}
+			var e osvEntry
+			if err := json.Unmarshal(data, &e); err != nil {
+				return xerrors.Errorf("Failed to parse %s: %w", path, err)
+			}
+			entries = append(entries, e)
+			return nil
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("Failed to walk OSV directory %s: %w", cnf.Path, err)
+		}
+	}
+
+	return entries, nil
+}
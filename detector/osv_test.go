@@ -0,0 +1,171 @@
+//go:build !scanner
+// +build !scanner
+
+package detector
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/future-architect/vuls/config"
+	"github.com/future-architect/vuls/logging"
+	"github.com/future-architect/vuls/models"
+)
+
+func TestOsvVersionAffected(t *testing.T) {
+	semverRange := osvAffected{
+		Ranges: []osvRange{
+			{
+				Type: "SEMVER",
+				Events: []osvRangeEvent{
+					{Introduced: "1.0.0"},
+					{Fixed: "1.2.0"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		installed string
+		affected  osvAffected
+		want      bool
+	}{
+		{"below introduced", "0.9.0", semverRange, false},
+		{"within range", "1.1.0", semverRange, true},
+		{"at fixed version", "1.2.0", semverRange, false},
+		{"above fixed version", "1.3.0", semverRange, false},
+		{
+			name:      "exact version list match",
+			installed: "2.0.0",
+			affected:  osvAffected{Versions: []string{"2.0.0"}},
+			want:      true,
+		},
+		{
+			name:      "git range is ignored for version comparison",
+			installed: "1.1.0",
+			affected: osvAffected{
+				Ranges: []osvRange{{Type: "GIT", Events: []osvRangeEvent{{Introduced: "abc123"}}}},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := osvVersionAffected(models.Gomod, tt.installed, tt.affected); got != tt.want {
+				t.Errorf("osvVersionAffected(%q) = %v, want %v", tt.installed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrimaryCveID(t *testing.T) {
+	tests := []struct {
+		name string
+		e    osvEntry
+		want string
+	}{
+		{"prefers CVE alias", osvEntry{ID: "GHSA-xxxx", Aliases: []string{"GHSA-xxxx", "CVE-2024-1234"}}, "CVE-2024-1234"},
+		{"falls back to OSV ID", osvEntry{ID: "GHSA-yyyy", Aliases: []string{"GHSA-yyyy"}}, "GHSA-yyyy"},
+	}
+	for _, tt := range tests {
+		if got := primaryCveID(tt.e); got != tt.want {
+			t.Errorf("%s: primaryCveID() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDetectPkgCvesWithOSVNewFinding(t *testing.T) {
+	dir := t.TempDir()
+	entry := osvEntry{
+		ID:      "GHSA-xxxx",
+		Aliases: []string{"CVE-2024-9999"},
+		Summary: "vulnerable lodash",
+		Affected: []osvAffected{{
+			Package: osvPackage{Ecosystem: "npm", Name: "lodash"},
+			Ranges: []osvRange{{
+				Type:   "SEMVER",
+				Events: []osvRangeEvent{{Introduced: "0"}, {Fixed: "4.17.21"}},
+			}},
+		}},
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("Failed to marshal OSV entry: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "GHSA-xxxx.json"), data, 0600); err != nil {
+		t.Fatalf("Failed to write OSV entry: %v", err)
+	}
+
+	r := &models.ScanResult{
+		ScannedCves: models.VulnInfos{},
+		LibraryScanners: []models.LibraryScanner{
+			{Type: models.NPM, Libs: []models.Library{{Name: "lodash", Version: "4.17.20"}}},
+		},
+	}
+
+	if err := DetectPkgCvesWithOSV(r, config.OSVConf{Path: dir}, logging.LogOpts{}); err != nil {
+		t.Fatalf("DetectPkgCvesWithOSV returned error: %v", err)
+	}
+
+	vinfo, ok := r.ScannedCves["CVE-2024-9999"]
+	if !ok {
+		t.Fatalf("expected CVE-2024-9999 to be detected, got %v", r.ScannedCves)
+	}
+	if len(vinfo.LibraryFixedIns) != 1 || vinfo.LibraryFixedIns[0].FixedIn != "4.17.21" {
+		t.Errorf("expected a LibraryFixedIn with FixedIn 4.17.21, got %+v", vinfo.LibraryFixedIns)
+	}
+}
+
+func TestDetectPkgCvesWithOSVMergesIntoExistingCve(t *testing.T) {
+	dir := t.TempDir()
+	entry := osvEntry{
+		ID:      "GHSA-yyyy",
+		Aliases: []string{"CVE-2024-8888"},
+		Summary: "already-known finding",
+		Affected: []osvAffected{{
+			Package:  osvPackage{Ecosystem: "npm", Name: "lodash"},
+			Versions: []string{"4.17.20"},
+		}},
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("Failed to marshal OSV entry: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "GHSA-yyyy.json"), data, 0600); err != nil {
+		t.Fatalf("Failed to write OSV entry: %v", err)
+	}
+
+	r := &models.ScanResult{
+		ScannedCves: models.VulnInfos{
+			"CVE-2024-8888": models.VulnInfo{CveID: "CVE-2024-8888"},
+		},
+		LibraryScanners: []models.LibraryScanner{
+			{Type: models.NPM, Libs: []models.Library{{Name: "lodash", Version: "4.17.20"}}},
+		},
+	}
+
+	if err := DetectPkgCvesWithOSV(r, config.OSVConf{Path: dir}, logging.LogOpts{}); err != nil {
+		t.Fatalf("DetectPkgCvesWithOSV returned error: %v", err)
+	}
+
+	vinfo := r.ScannedCves["CVE-2024-8888"]
+	found := false
+	for _, c := range vinfo.Confidences {
+		if c == models.OSVMatch {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected OSVMatch confidence on the merged VulnInfo, got %v", vinfo.Confidences)
+	}
+	if len(vinfo.LibraryFixedIns) != 1 {
+		t.Errorf("expected OSV's LibraryFixedIn to be merged into the existing VulnInfo, got %+v", vinfo.LibraryFixedIns)
+	}
+	if _, ok := vinfo.CveContents[models.OSV]; !ok {
+		t.Errorf("expected OSV's CveContents to be merged into the existing VulnInfo")
+	}
+}
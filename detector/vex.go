@@ -0,0 +1,244 @@
+//go:build !scanner
+// +build !scanner
+
+package detector
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/future-architect/vuls/logging"
+	"github.com/future-architect/vuls/models"
+)
+
+// vexDocument is the subset of an OpenVEX / CSAF-VEX document vuls needs.
+// It is intentionally loose about the surrounding document metadata and
+// only keeps the fields required to resolve a product+CVE to a status.
+type vexDocument struct {
+	Statements []vexStatement `json:"statements"`
+}
+
+// vexStatement mirrors the fields OpenVEX and CSAF-VEX statements share.
+type vexStatement struct {
+	Vulnerability vexVulnerability `json:"vulnerability"`
+	Products      []vexProduct     `json:"products"`
+	Status        string           `json:"status"`
+	Justification string           `json:"justification"`
+	Timestamp     *time.Time       `json:"timestamp,omitempty"`
+	LastUpdated   *time.Time       `json:"last_updated,omitempty"`
+}
+
+type vexVulnerability struct {
+	Name string `json:"name"`
+}
+
+type vexProduct struct {
+	ID   string `json:"@id"`
+	CPE  string `json:"cpe,omitempty"`
+	PURL string `json:"purl,omitempty"`
+}
+
+// effectiveTime returns the timestamp that determines precedence between
+// two statements about the same CVE.
+func (s vexStatement) effectiveTime() time.Time {
+	if s.LastUpdated != nil {
+		return *s.LastUpdated
+	}
+	if s.Timestamp != nil {
+		return *s.Timestamp
+	}
+	return time.Time{}
+}
+
+// DetectWithVEX reads OpenVEX / CSAF-VEX documents from paths and applies
+// their statements to r.ScannedCves. A statement only applies when at least
+// one of its products matches something actually found on the host (see
+// vexAppliesToHost); otherwise it's ignored, even if its vulnerability name
+// matches a scanned CVE. not_affected statements remove the CVE from the
+// result (recording the justification for reporting), affected statements
+// raise confidence, and under_investigation/fixed statements are recorded
+// without changing detection results.
+//
+// Later statements (by last_updated, else timestamp) win over earlier ones
+// for the same CVE; ties, including statements with no time at all, are
+// broken by file order. Precedence is per CVE, not per product: of the
+// statements that apply to the host, the latest one decides the CVE even
+// when it names a different product than an earlier one.
+func DetectWithVEX(r *models.ScanResult, paths []string) error {
+	statements := []vexStatement{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return xerrors.Errorf("Failed to read VEX document %s: %w", path, err)
+		}
+
+		var doc vexDocument
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return xerrors.Errorf("Failed to parse VEX document %s: %w", path, err)
+		}
+		statements = append(statements, doc.Statements...)
+	}
+
+	// Later statements override earlier ones for the same CVE.
+	sort.SliceStable(statements, func(i, j int) bool {
+		return statements[i].effectiveTime().Before(statements[j].effectiveTime())
+	})
+
+	latest := map[string]vexStatement{}
+	for _, st := range statements {
+		cveID := st.Vulnerability.Name
+		if cveID == "" {
+			continue
+		}
+		vinfo, ok := r.ScannedCves[cveID]
+		if !ok {
+			continue
+		}
+		if !vexAppliesToHost(r, vinfo, st) {
+			continue
+		}
+		// Sorted ascending by effectiveTime, so the last write per CVE wins.
+		latest[cveID] = st
+	}
+
+	nNotAffected, nAffected, nUnjustified := 0, 0, 0
+	for cveID, st := range latest {
+		vinfo := r.ScannedCves[cveID]
+
+		switch st.Status {
+		case "not_affected":
+			if st.Justification == "" {
+				logging.Log.Warnf("%s: VEX statement for %s is not_affected but has no justification, suppressing with a placeholder justification for audit", r.FormatServerName(), cveID)
+				vinfo.VEXJustification = unjustifiedNotAffected
+				nUnjustified++
+			} else {
+				vinfo.VEXJustification = st.Justification
+			}
+			if r.VEXSuppressedCves == nil {
+				r.VEXSuppressedCves = models.VulnInfos{}
+			}
+			r.VEXSuppressedCves[cveID] = vinfo
+			delete(r.ScannedCves, cveID)
+			nNotAffected++
+		case "affected":
+			vinfo.Confidences.AppendIfMissing(models.VEXAffected)
+			r.ScannedCves[cveID] = vinfo
+			nAffected++
+		case "under_investigation", "fixed":
+			vinfo.VEXJustification = st.Justification
+			r.ScannedCves[cveID] = vinfo
+		}
+	}
+
+	logging.Log.Infof("%s: %d CVEs suppressed (%d without a justification), %d CVEs confirmed by VEX", r.FormatServerName(), nNotAffected, nUnjustified, nAffected)
+	return nil
+}
+
+// unjustifiedNotAffected marks a suppressed CVE whose VEX statement omitted
+// the required justification, so operators auditing VEXSuppressedCves can
+// tell a genuinely-justified suppression from an incomplete feed.
+const unjustifiedNotAffected = "UNSPECIFIED: VEX statement omitted justification"
+
+// vexAppliesToHost reports whether at least one of st's products matches
+// something vuls actually found on the host for cveID: a CPE already
+// recorded against vinfo by DetectCpeURIsCves, or a PURL matching one of the
+// libraries DetectLibsCves/DetectPkgCvesWithOSV scanned. A product that
+// carries neither a CPE nor a PURL (just an opaque @id) can't be compared
+// against the host at all, so it's treated as applying - the same stance
+// DetectCpeURIsCves takes when a feed gives it nothing more specific to key
+// on.
+func vexAppliesToHost(r *models.ScanResult, vinfo models.VulnInfo, st vexStatement) bool {
+	for _, p := range st.Products {
+		if p.CPE == "" && p.PURL == "" {
+			return true
+		}
+		if p.CPE != "" {
+			for _, cpe := range vinfo.CpeURIs {
+				if cpeMatches(p.CPE, cpe) {
+					return true
+				}
+			}
+		}
+		if p.PURL != "" {
+			for _, scanner := range r.LibraryScanners {
+				for _, lib := range scanner.Libs {
+					if purlMatches(p.PURL, scanner.Type, lib) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// cpeMatches compares two CPE URIs (2.2 or 2.3 form) component-wise, the
+// same loose matching CPE dictionaries use: a "*" or missing component on
+// either side matches anything, every other component must match exactly.
+func cpeMatches(a, b string) bool {
+	pa := strings.Split(a, ":")
+	pb := strings.Split(b, ":")
+	n := len(pa)
+	if len(pb) < n {
+		n = len(pb)
+	}
+	for i := 0; i < n; i++ {
+		fa, fb := pa[i], pb[i]
+		if fa == "" || fb == "" || fa == "*" || fb == "*" {
+			continue
+		}
+		if fa != fb {
+			return false
+		}
+	}
+	return true
+}
+
+// purlTypes maps a vuls LibraryType to the package-url type the libraries it
+// scans are published under, the inverse of the osvEcosystems-style tables
+// elsewhere in this package.
+var purlTypes = map[models.LibraryType]string{
+	models.Gomod:    "golang",
+	models.NPM:      "npm",
+	models.Pip:      "pypi",
+	models.Bundler:  "gem",
+	models.Maven:    "maven",
+	models.Nuget:    "nuget",
+	models.Cargo:    "cargo",
+	models.Composer: "composer",
+}
+
+// purlMatches reports whether a VEX product's PURL identifies lib, ignoring
+// any namespace component and treating a PURL with no version as matching
+// any installed version.
+func purlMatches(purl string, ltype models.LibraryType, lib models.Library) bool {
+	wantType, ok := purlTypes[ltype]
+	if !ok {
+		return false
+	}
+
+	rest := strings.TrimPrefix(purl, "pkg:")
+	typeAndRest := strings.SplitN(rest, "/", 2)
+	if len(typeAndRest) != 2 || typeAndRest[0] != wantType {
+		return false
+	}
+
+	nameAndVersion := typeAndRest[1]
+	name, version := nameAndVersion, ""
+	if idx := strings.LastIndex(nameAndVersion, "@"); idx >= 0 {
+		name, version = nameAndVersion[:idx], nameAndVersion[idx+1:]
+	}
+	if segs := strings.Split(name, "/"); len(segs) > 0 {
+		name = segs[len(segs)-1]
+	}
+
+	if name != lib.Name {
+		return false
+	}
+	return version == "" || version == lib.Version
+}
@@ -0,0 +1,189 @@
+//go:build !scanner
+// +build !scanner
+
+package detector
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/future-architect/vuls/models"
+)
+
+func writeVEXDoc(t *testing.T, dir, name string, doc vexDocument) string {
+	t.Helper()
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Failed to marshal VEX doc: %v", err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Failed to write VEX doc: %v", err)
+	}
+	return path
+}
+
+func TestDetectWithVEXNotAffectedWithoutJustification(t *testing.T) {
+	dir := t.TempDir()
+	path := writeVEXDoc(t, dir, "vex.json", vexDocument{
+		Statements: []vexStatement{
+			{
+				Vulnerability: vexVulnerability{Name: "CVE-2024-0001"},
+				Products:      []vexProduct{{ID: "pkg:generic/app"}},
+				Status:        "not_affected",
+			},
+		},
+	})
+
+	r := &models.ScanResult{
+		ScannedCves: models.VulnInfos{
+			"CVE-2024-0001": models.VulnInfo{CveID: "CVE-2024-0001"},
+		},
+	}
+
+	if err := DetectWithVEX(r, []string{path}); err != nil {
+		t.Fatalf("DetectWithVEX returned error: %v", err)
+	}
+
+	if _, ok := r.ScannedCves["CVE-2024-0001"]; ok {
+		t.Errorf("expected CVE-2024-0001 to be suppressed out of ScannedCves")
+	}
+
+	suppressed, ok := r.VEXSuppressedCves["CVE-2024-0001"]
+	if !ok {
+		t.Fatalf("expected CVE-2024-0001 to be recorded in VEXSuppressedCves despite missing justification")
+	}
+	if suppressed.VEXJustification == "" {
+		t.Errorf("expected a placeholder justification to be recorded, got empty string")
+	}
+}
+
+func TestDetectWithVEXNotAffectedWithJustification(t *testing.T) {
+	dir := t.TempDir()
+	path := writeVEXDoc(t, dir, "vex.json", vexDocument{
+		Statements: []vexStatement{
+			{
+				Vulnerability: vexVulnerability{Name: "CVE-2024-0002"},
+				Products:      []vexProduct{{ID: "pkg:generic/app"}},
+				Status:        "not_affected",
+				Justification: "component_not_present",
+			},
+		},
+	})
+
+	r := &models.ScanResult{
+		ScannedCves: models.VulnInfos{
+			"CVE-2024-0002": models.VulnInfo{CveID: "CVE-2024-0002"},
+		},
+	}
+
+	if err := DetectWithVEX(r, []string{path}); err != nil {
+		t.Fatalf("DetectWithVEX returned error: %v", err)
+	}
+
+	suppressed, ok := r.VEXSuppressedCves["CVE-2024-0002"]
+	if !ok {
+		t.Fatalf("expected CVE-2024-0002 to be suppressed")
+	}
+	if suppressed.VEXJustification != "component_not_present" {
+		t.Errorf("got justification %q, want %q", suppressed.VEXJustification, "component_not_present")
+	}
+}
+
+func TestDetectWithVEXIgnoresStatementForUnrelatedCPEProduct(t *testing.T) {
+	dir := t.TempDir()
+	path := writeVEXDoc(t, dir, "vex.json", vexDocument{
+		Statements: []vexStatement{
+			{
+				Vulnerability: vexVulnerability{Name: "CVE-2024-0003"},
+				Products:      []vexProduct{{CPE: "cpe:2.3:a:other-vendor:other-product:1.0:*:*:*:*:*:*:*"}},
+				Status:        "not_affected",
+				Justification: "component_not_present",
+			},
+		},
+	})
+
+	r := &models.ScanResult{
+		ScannedCves: models.VulnInfos{
+			"CVE-2024-0003": models.VulnInfo{
+				CveID:   "CVE-2024-0003",
+				CpeURIs: []string{"cpe:2.3:a:acme:widget:2.0:*:*:*:*:*:*:*"},
+			},
+		},
+	}
+
+	if err := DetectWithVEX(r, []string{path}); err != nil {
+		t.Fatalf("DetectWithVEX returned error: %v", err)
+	}
+
+	if _, ok := r.ScannedCves["CVE-2024-0003"]; !ok {
+		t.Errorf("expected CVE-2024-0003 to remain, VEX statement's product doesn't match the host's CPE")
+	}
+	if _, ok := r.VEXSuppressedCves["CVE-2024-0003"]; ok {
+		t.Errorf("expected CVE-2024-0003 not to be suppressed by an unrelated product's statement")
+	}
+}
+
+func TestDetectWithVEXAppliesStatementForMatchingCPEProduct(t *testing.T) {
+	dir := t.TempDir()
+	path := writeVEXDoc(t, dir, "vex.json", vexDocument{
+		Statements: []vexStatement{
+			{
+				Vulnerability: vexVulnerability{Name: "CVE-2024-0004"},
+				Products:      []vexProduct{{CPE: "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*"}},
+				Status:        "not_affected",
+				Justification: "component_not_present",
+			},
+		},
+	})
+
+	r := &models.ScanResult{
+		ScannedCves: models.VulnInfos{
+			"CVE-2024-0004": models.VulnInfo{
+				CveID:   "CVE-2024-0004",
+				CpeURIs: []string{"cpe:2.3:a:acme:widget:2.0:*:*:*:*:*:*:*"},
+			},
+		},
+	}
+
+	if err := DetectWithVEX(r, []string{path}); err != nil {
+		t.Fatalf("DetectWithVEX returned error: %v", err)
+	}
+
+	if _, ok := r.VEXSuppressedCves["CVE-2024-0004"]; !ok {
+		t.Errorf("expected CVE-2024-0004 to be suppressed, VEX statement's product CPE matches the host's")
+	}
+}
+
+func TestDetectWithVEXAppliesStatementForMatchingPURLProduct(t *testing.T) {
+	dir := t.TempDir()
+	path := writeVEXDoc(t, dir, "vex.json", vexDocument{
+		Statements: []vexStatement{
+			{
+				Vulnerability: vexVulnerability{Name: "CVE-2024-0005"},
+				Products:      []vexProduct{{PURL: "pkg:npm/lodash@4.17.20"}},
+				Status:        "not_affected",
+				Justification: "component_not_present",
+			},
+		},
+	})
+
+	r := &models.ScanResult{
+		ScannedCves: models.VulnInfos{
+			"CVE-2024-0005": models.VulnInfo{CveID: "CVE-2024-0005"},
+		},
+		LibraryScanners: []models.LibraryScanner{
+			{Type: models.NPM, Libs: []models.Library{{Name: "lodash", Version: "4.17.20"}}},
+		},
+	}
+
+	if err := DetectWithVEX(r, []string{path}); err != nil {
+		t.Fatalf("DetectWithVEX returned error: %v", err)
+	}
+
+	if _, ok := r.VEXSuppressedCves["CVE-2024-0005"]; !ok {
+		t.Errorf("expected CVE-2024-0005 to be suppressed, VEX statement's product PURL matches a scanned library")
+	}
+}
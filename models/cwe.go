@@ -0,0 +1,12 @@
+package models
+
+import "github.com/future-architect/vuls/cwe"
+
+// CweDictEntry holds a CWE entry's rankings and localized descriptions.
+type CweDictEntry struct {
+	En                   *cwe.Cwe
+	Ja                   *cwe.Cwe
+	OwaspTopTen2017      string
+	CweTopTwentyfive2019 string
+	SansTopTwentyfive    string
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// LibraryType identifies the language/ecosystem a library scanner found
+// packages for.
+type LibraryType string
+
+// LibraryType values.
+const (
+	Bundler  LibraryType = "bundler"
+	Cargo    LibraryType = "cargo"
+	Composer LibraryType = "composer"
+	Gomod    LibraryType = "gomod"
+	Maven    LibraryType = "maven"
+	NPM      LibraryType = "npm"
+	Nuget    LibraryType = "nuget"
+	Pip      LibraryType = "pip"
+)
+
+// LibraryScanner is the set of libraries found for one lockfile/binary,
+// along with the path it was found at.
+type LibraryScanner struct {
+	Type         LibraryType
+	LockfilePath string
+	Path         string
+	Libs         []Library
+}
+
+// Library is a single scanned library dependency.
+type Library struct {
+	Name    string
+	Version string
+}
+
+// LibraryFixedIn records, for a single library match, which source found it
+// and what version fixes it.
+type LibraryFixedIn struct {
+	Key     string
+	Name    string
+	FixedIn string
+}
+
+// CompareVersion compares two version strings for the given library
+// ecosystem, returning <0, 0, or >0 as installed is before, equal to, or
+// after other. Go modules use plain semver; every other ecosystem (npm,
+// PyPI, RubyGems, Maven, NuGet, Packagist, ...) uses compareVersionParts,
+// a numeric component-wise comparison - none of them are lexicographically
+// ordered (e.g. "9.5.0" sorts after "10.0.0" as plain strings).
+func CompareVersion(libType LibraryType, installed, other string) int {
+	switch libType {
+	case Gomod:
+		v1, v2 := installed, other
+		if !strings.HasPrefix(v1, "v") {
+			v1 = "v" + v1
+		}
+		if !strings.HasPrefix(v2, "v") {
+			v2 = "v" + v2
+		}
+		if semver.IsValid(v1) && semver.IsValid(v2) {
+			return semver.Compare(v1, v2)
+		}
+		return strings.Compare(installed, other)
+	default:
+		return compareVersionParts(installed, other)
+	}
+}
+
+// versionPartRe splits a version string into its alternating
+// numeric/non-numeric runs, e.g. "1.2.0-beta.3" -> ["1",".","2",".","0",
+// "-beta.", "3"], so each run can be compared with the right semantics.
+var versionPartRe = regexp.MustCompile(`[0-9]+|[^0-9]+`)
+
+// compareVersionParts compares two version strings component-wise: numeric
+// runs compare numerically (so "10" > "9"), non-numeric runs compare
+// lexicographically, and a version that runs out of components first
+// compares as lower (so "1.2" < "1.2.1"). This covers the common case for
+// npm/PyPI/RubyGems/Maven/NuGet/Packagist version schemes without needing a
+// dedicated parser per ecosystem.
+func compareVersionParts(a, b string) int {
+	aParts := versionPartRe.FindAllString(a, -1)
+	bParts := versionPartRe.FindAllString(b, -1)
+
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		if i >= len(aParts) {
+			return -1
+		}
+		if i >= len(bParts) {
+			return 1
+		}
+		ap, bp := aParts[i], bParts[i]
+
+		an, aErr := strconv.Atoi(ap)
+		bn, bErr := strconv.Atoi(bp)
+		if aErr == nil && bErr == nil {
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+			continue
+		}
+
+		if ap != bp {
+			return strings.Compare(ap, bp)
+		}
+	}
+	return 0
+}
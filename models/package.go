@@ -0,0 +1,126 @@
+package models
+
+import (
+	"net"
+	"strconv"
+
+	"golang.org/x/xerrors"
+)
+
+// PackageFixStatus is the normalized fix-status vocabulary --ignore-status
+// filters against, shared across every source that can set it (gost, OVAL,
+// and the legacy free-form FixState string on older JSON results).
+type PackageFixStatus string
+
+// PackageFixStatus values, following the Red Hat/Ubuntu vocabulary gost and
+// OVAL advisories use.
+const (
+	StatusAffected           PackageFixStatus = "affected"
+	StatusFixed              PackageFixStatus = "fixed"
+	StatusNotAffected        PackageFixStatus = "not_affected"
+	StatusUnderInvestigation PackageFixStatus = "under_investigation"
+	StatusWillNotFix         PackageFixStatus = "will_not_fix"
+	StatusFixDeferred        PackageFixStatus = "fix_deferred"
+	StatusEndOfLife          PackageFixStatus = "end_of_life"
+	StatusUnknown            PackageFixStatus = "unknown"
+)
+
+// NewPackageFixStatus guesses a PackageFixStatus from the legacy free-form
+// FixState string gost/OVAL used to leave AffectedPackage.Status empty and
+// report instead. It only exists to let --ignore-status work against
+// results detected before Status was populated from structured data; new
+// results should have Status set directly by detectPkgsCvesWithGost/Oval.
+func NewPackageFixStatus(fixState string) PackageFixStatus {
+	switch fixState {
+	case "":
+		return StatusUnknown
+	case "Not fixed yet":
+		return StatusAffected
+	default:
+		return StatusUnknown
+	}
+}
+
+// AffectedPackage : Affected Package
+type AffectedPackage struct {
+	Name            string
+	Source          string
+	CpeURI          string
+	FixState        string
+	Status          PackageFixStatus
+	NotFixedYet     bool
+	CveContentTypes []CveContentType
+}
+
+// Packages is a map of Package, keyed by package name.
+type Packages map[string]Package
+
+// Package has installed packages.
+type Package struct {
+	Name            string
+	Version         string
+	Release         string
+	NewVersion      string
+	NewRelease      string
+	Arch            string
+	Repo            string
+	Changelog       Changelog
+	AffectedProcs   []AffectedProcess
+	NotFixedYet     bool
+	ModularityLabel string
+}
+
+// Changelog has the changelog of a package and its fetch method.
+type Changelog struct {
+	Contents string
+	Method   string
+}
+
+// AffectedProcess holds a running process that has the target package loaded,
+// and the listen ports (if any) it's serving on.
+type AffectedProcess struct {
+	PID             string
+	Name            string
+	ListenPorts     []string
+	ListenPortStats []PortStat
+}
+
+// PortStat holds a parsed "ip:port" listen address.
+type PortStat struct {
+	BindAddress string
+	Port        string
+}
+
+// NewPortStat parses an "ip:port" string as reported by lsof/ss into a PortStat.
+func NewPortStat(ipPort string) (*PortStat, error) {
+	ip, port, err := net.SplitHostPort(ipPort)
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to parse %s as ip:port: %w", ipPort, err)
+	}
+	if _, err := strconv.Atoi(port); err != nil {
+		return nil, xerrors.Errorf("Failed to parse port %s: %w", port, err)
+	}
+	return &PortStat{BindAddress: ip, Port: port}, nil
+}
+
+// SrcPackages is a map of SrcPackage, keyed by source package name.
+type SrcPackages map[string]SrcPackage
+
+// SrcPackage represents a source package, which may produce multiple binary packages.
+type SrcPackage struct {
+	Name        string
+	Version     string
+	BinaryNames []string
+}
+
+// WordPressPackages is a list of WordPress core/plugin/theme packages.
+type WordPressPackages []WordPressPackage
+
+// WordPressPackage is a scanned WordPress core, plugin, or theme.
+type WordPressPackage struct {
+	Name    string
+	Type    string
+	Status  string
+	Version string
+	Update  string
+}
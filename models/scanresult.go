@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"github.com/future-architect/vuls/config"
+)
+
+// Container identifies the container a ScanResult was scanned inside, if any.
+type Container struct {
+	ContainerID string
+	Name        string
+}
+
+// ScanResult holds everything scanned and detected for a single host or container.
+type ScanResult struct {
+	ServerName string
+	Container  Container
+	Family     string
+	Release    string
+	ScannedBy  string
+
+	Packages          Packages
+	SrcPackages       SrcPackages
+	WordPressPackages WordPressPackages
+	LibraryScanners   []LibraryScanner
+
+	ScannedCves       VulnInfos
+	VEXSuppressedCves VulnInfos
+	CweDict           map[string]CweDictEntry
+
+	Lang             string
+	ReportedBy       string
+	ReportedAt       time.Time
+	ReportedVersion  string
+	ReportedRevision string
+
+	Config struct {
+		Report config.Config
+	}
+}
+
+// FormatServerName returns the name used to identify this result in logs,
+// qualifying it with the container name when the result is for a container.
+func (r ScanResult) FormatServerName() string {
+	if r.Container.ContainerID == "" {
+		return r.ServerName
+	}
+	return r.ServerName + "@" + r.Container.Name
+}
+
+// ServerInfo returns a human-readable description of the scanned target,
+// used in error messages.
+func (r ScanResult) ServerInfo() string {
+	if r.Container.ContainerID == "" {
+		return r.ServerName
+	}
+	return r.ServerName + "@" + r.Container.Name + "(" + r.Container.ContainerID + ")"
+}
+
+// ClearFields removes the top-level ScanResult fields named in keys before
+// the result is written out, so operators can keep noisy package data out
+// of the JSON report. Only "Packages", "SrcPackages" and "WordPressPackages"
+// are recognised; any other key is ignored.
+func (r ScanResult) ClearFields(keys []string) ScanResult {
+	for _, key := range keys {
+		switch key {
+		case "Packages":
+			r.Packages = nil
+		case "SrcPackages":
+			r.SrcPackages = nil
+		case "WordPressPackages":
+			r.WordPressPackages = nil
+		}
+	}
+	return r
+}
+
+// RemoveRaspbianPackFromResult drops the Raspbian-specific raspi-firmware
+// package, which OVAL/gost don't carry advisories for and would otherwise
+// be reported as an undetectable package.
+func (r *ScanResult) RemoveRaspbianPackFromResult() *ScanResult {
+	delete(r.Packages, "raspi-firmware")
+	return r
+}
+
+// FilterInactiveWordPressLibs drops inactive WordPress plugins/themes from
+// the result unless detectInactive is set.
+func (r *ScanResult) FilterInactiveWordPressLibs(detectInactive bool) {
+	if detectInactive {
+		return
+	}
+	active := WordPressPackages{}
+	for _, p := range r.WordPressPackages {
+		if p.Status == "active" {
+			active = append(active, p)
+		}
+	}
+	r.WordPressPackages = active
+}
@@ -0,0 +1,356 @@
+package models
+
+import (
+	"regexp"
+
+	"github.com/future-architect/vuls/logging"
+	cvemodels "github.com/vulsio/go-cve-dictionary/models"
+)
+
+// VulnInfos is a map of VulnInfo, keyed by CVE ID.
+type VulnInfos map[string]VulnInfo
+
+// VulnInfo holds the detected CVE, its confidence, and detail content
+// gathered from every source that matched it.
+type VulnInfo struct {
+	CveID            string
+	Confidences      Confidences
+	AffectedPackages []AffectedPackage
+	CpeURIs          []string
+	DistroAdvisories []DistroAdvisory
+	CveContents      CveContents
+	Exploits         []Exploit
+	Mitigations      []Mitigation
+	AlertDict        AlertDict
+	VEXJustification string
+	LibraryFixedIns  []LibraryFixedIn
+}
+
+// FilterByCvssOver drops VulnInfos whose max CVSS score is below over,
+// returning the filtered set and the number of entries dropped.
+func (v VulnInfos) FilterByCvssOver(over float64) (VulnInfos, int) {
+	filtered := VulnInfos{}
+	for id, vinfo := range v {
+		if vinfo.MaxCvssScore() >= over {
+			filtered[id] = vinfo
+		}
+	}
+	return filtered, len(v) - len(filtered)
+}
+
+// FilterUnfixed drops VulnInfos all of whose AffectedPackages are
+// NotFixedYet, when ignoreUnfixed is set.
+func (v VulnInfos) FilterUnfixed(ignoreUnfixed bool) (VulnInfos, int) {
+	if !ignoreUnfixed {
+		return v, 0
+	}
+	filtered := VulnInfos{}
+	for id, vinfo := range v {
+		if len(vinfo.AffectedPackages) == 0 {
+			filtered[id] = vinfo
+			continue
+		}
+		allUnfixed := true
+		for _, p := range vinfo.AffectedPackages {
+			if !p.NotFixedYet {
+				allUnfixed = false
+				break
+			}
+		}
+		if !allUnfixed {
+			filtered[id] = vinfo
+		}
+	}
+	return filtered, len(v) - len(filtered)
+}
+
+// FilterByStatuses drops VulnInfos all of whose AffectedPackages carry one
+// of the given PackageFixStatus values (e.g. --ignore-status=will_not_fix),
+// so operators can hide CVEs vendors have already triaged as irrelevant.
+func (v VulnInfos) FilterByStatuses(statuses []string) (VulnInfos, int) {
+	ignore := map[PackageFixStatus]bool{}
+	for _, s := range statuses {
+		ignore[PackageFixStatus(s)] = true
+	}
+
+	filtered := VulnInfos{}
+	for id, vinfo := range v {
+		if len(vinfo.AffectedPackages) == 0 {
+			filtered[id] = vinfo
+			continue
+		}
+		allIgnored := true
+		for _, p := range vinfo.AffectedPackages {
+			if !ignore[p.Status] {
+				allIgnored = false
+				break
+			}
+		}
+		if !allIgnored {
+			filtered[id] = vinfo
+		}
+	}
+	return filtered, len(v) - len(filtered)
+}
+
+// FilterByConfidenceOver drops VulnInfos whose max confidence score is below over.
+func (v VulnInfos) FilterByConfidenceOver(over int) (VulnInfos, int) {
+	filtered := VulnInfos{}
+	for id, vinfo := range v {
+		max := 0
+		for _, c := range vinfo.Confidences {
+			if c.Score > max {
+				max = c.Score
+			}
+		}
+		if max >= over {
+			filtered[id] = vinfo
+		}
+	}
+	return filtered, len(v) - len(filtered)
+}
+
+// FilterIgnoreCves drops the CVE IDs listed in ignoreCves.
+func (v VulnInfos) FilterIgnoreCves(ignoreCves []string) (VulnInfos, int) {
+	ignore := map[string]bool{}
+	for _, id := range ignoreCves {
+		ignore[id] = true
+	}
+	filtered := VulnInfos{}
+	for id, vinfo := range v {
+		if !ignore[id] {
+			filtered[id] = vinfo
+		}
+	}
+	return filtered, len(v) - len(filtered)
+}
+
+// FilterIgnorePkgs drops VulnInfos all of whose AffectedPackages match one
+// of the given regexps.
+func (v VulnInfos) FilterIgnorePkgs(ignorePkgsRegexps []string) (VulnInfos, int) {
+	regexps := make([]*regexp.Regexp, 0, len(ignorePkgsRegexps))
+	for _, s := range ignorePkgsRegexps {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			logging.Log.Warnf("Failed to compile ignorePkgsRegexp %s, err: %+v", s, err)
+			continue
+		}
+		regexps = append(regexps, re)
+	}
+
+	filtered := VulnInfos{}
+	for id, vinfo := range v {
+		if len(vinfo.AffectedPackages) == 0 {
+			filtered[id] = vinfo
+			continue
+		}
+		allIgnored := true
+		for _, p := range vinfo.AffectedPackages {
+			matched := false
+			for _, re := range regexps {
+				if re.MatchString(p.Name) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				allIgnored = false
+				break
+			}
+		}
+		if !allIgnored {
+			filtered[id] = vinfo
+		}
+	}
+	return filtered, len(v) - len(filtered)
+}
+
+// FindScoredVulns drops VulnInfos with no CVSS score from any source.
+func (v VulnInfos) FindScoredVulns() (VulnInfos, int) {
+	filtered := VulnInfos{}
+	for id, vinfo := range v {
+		if vinfo.MaxCvssScore() > 0 {
+			filtered[id] = vinfo
+		}
+	}
+	return filtered, len(v) - len(filtered)
+}
+
+// MaxCvssScore returns the highest CVSS score among all of this VulnInfo's CveContents.
+func (v VulnInfo) MaxCvssScore() float64 {
+	max := 0.0
+	for _, conts := range v.CveContents {
+		for _, cont := range conts {
+			if cont.Cvss3Score > max {
+				max = cont.Cvss3Score
+			}
+			if cont.Cvss2Score > max {
+				max = cont.Cvss2Score
+			}
+		}
+	}
+	return max
+}
+
+// Confidence represents how strongly a detection method trusts a match,
+// used both to pick the best match among sources and to filter with
+// --confidence-over.
+type Confidence struct {
+	Score           int
+	DetectionMethod string
+}
+
+// Confidences is a list of Confidence.
+type Confidences []Confidence
+
+// AppendIfMissing appends c to *cs unless a Confidence with the same
+// DetectionMethod is already present.
+func (cs *Confidences) AppendIfMissing(c Confidence) {
+	for _, existing := range *cs {
+		if existing.DetectionMethod == c.DetectionMethod {
+			return
+		}
+	}
+	*cs = append(*cs, c)
+}
+
+// Named Confidence values. A higher Score means the detection method narrows
+// down a true positive more.
+var (
+	JvnVendorProductMatch = Confidence{Score: 50, DetectionMethod: "JvnVendorProductMatch"}
+	NvdExactVersionMatch  = Confidence{Score: 100, DetectionMethod: "NvdExactVersionMatch"}
+	NvdRoughVersionMatch  = Confidence{Score: 80, DetectionMethod: "NvdRoughVersionMatch"}
+	NvdVendorProductMatch = Confidence{Score: 50, DetectionMethod: "NvdVendorProductMatch"}
+	OSVMatch              = Confidence{Score: 100, DetectionMethod: "OSVMatch"}
+	VEXAffected           = Confidence{Score: 100, DetectionMethod: "VEXAffected"}
+	GoModuleVersionMatch  = Confidence{Score: 70, DetectionMethod: "GoModuleVersionMatch"}
+	GoSymbolReachable     = Confidence{Score: 100, DetectionMethod: "GoSymbolReachable"}
+)
+
+// CveContentType identifies the source a CveContent came from.
+type CveContentType string
+
+// CveContentType values.
+const (
+	NvdXML CveContentType = "nvd"
+	Jvn    CveContentType = "jvn"
+	OSV    CveContentType = "osv"
+)
+
+// CveContents is a map of CveContent slices, keyed by source.
+type CveContents map[CveContentType][]CveContent
+
+// CveContent holds one source's description of a CVE.
+type CveContent struct {
+	Type          CveContentType
+	CveID         string
+	Summary       string
+	Cvss2Score    float64
+	Cvss3Score    float64
+	Cvss3Severity string
+	SourceLink    string
+	CweIDs        []string
+	References    []Reference
+}
+
+// Empty reports whether this CveContent carries no useful data, so callers
+// can skip merging it in.
+func (c CveContent) Empty() bool {
+	return c.Summary == "" && c.Cvss2Score == 0 && c.Cvss3Score == 0
+}
+
+// Reference is a link to further information about a CVE.
+type Reference struct {
+	Source string
+	Link   string
+}
+
+// DistroAdvisory is a distribution-issued advisory ID for a CVE (e.g. RHSA, JVN).
+type DistroAdvisory struct {
+	AdvisoryID string
+}
+
+// Alert is a single CERT alert referencing a CVE.
+type Alert struct {
+	URL   string
+	Title string
+	Team  string
+}
+
+// AlertDict groups CERT alerts by the team that issued them.
+type AlertDict struct {
+	USCERT []Alert
+	JPCERT []Alert
+}
+
+// Exploit is a known proof-of-concept or exploit for a CVE.
+type Exploit struct {
+	ExploitType string
+	URL         string
+	Description string
+}
+
+// Mitigation is known mitigation advice for a CVE.
+type Mitigation struct {
+	URL         string
+	Description string
+}
+
+// ConvertNvdToModel converts go-cve-dictionary's NVD feed entries into
+// CveContents, along with any exploits/mitigations NVD references carry.
+func ConvertNvdToModel(cveID string, nvds []cvemodels.Nvd) ([]CveContent, []Exploit, []Mitigation) {
+	contents := make([]CveContent, 0, len(nvds))
+	exploits := []Exploit{}
+	mitigations := []Mitigation{}
+	for _, nvd := range nvds {
+		cweIDs := make([]string, 0, len(nvd.Cwes))
+		for _, c := range nvd.Cwes {
+			cweIDs = append(cweIDs, c.Value)
+		}
+		refs := make([]Reference, 0, len(nvd.References))
+		for _, r := range nvd.References {
+			refs = append(refs, Reference{Source: r.Source, Link: r.Link})
+			for _, tag := range r.Tags {
+				switch tag {
+				case "Exploit":
+					exploits = append(exploits, Exploit{ExploitType: "Exploit", URL: r.Link})
+				case "Mitigation", "Patch":
+					mitigations = append(mitigations, Mitigation{URL: r.Link})
+				}
+			}
+		}
+		contents = append(contents, CveContent{
+			Type:          NvdXML,
+			CveID:         cveID,
+			Summary:       nvd.Summary,
+			Cvss2Score:    nvd.Cvss2Score,
+			Cvss3Score:    nvd.Cvss3Score,
+			Cvss3Severity: nvd.Cvss3Severity,
+			SourceLink:    nvd.SourceLink,
+			CweIDs:        cweIDs,
+			References:    refs,
+		})
+	}
+	return contents, exploits, mitigations
+}
+
+// ConvertJvnToModel converts go-cve-dictionary's JVN feed entries into CveContents.
+func ConvertJvnToModel(cveID string, jvns []cvemodels.Jvn) []CveContent {
+	contents := make([]CveContent, 0, len(jvns))
+	for _, jvn := range jvns {
+		refs := make([]Reference, 0, len(jvn.References))
+		for _, r := range jvn.References {
+			refs = append(refs, Reference{Source: r.Source, Link: r.Link})
+		}
+		contents = append(contents, CveContent{
+			Type:       Jvn,
+			CveID:      cveID,
+			Summary:    jvn.Summary,
+			Cvss2Score: jvn.Cvss2Score,
+			Cvss3Score: jvn.Cvss3Score,
+			SourceLink: jvn.SourceLink,
+			References: refs,
+		})
+	}
+	return contents
+}